@@ -2,9 +2,15 @@
 package safedown
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 )
 
 // Order represents the order that the shutdown actions will be executed.
@@ -23,17 +29,192 @@ const (
 	PerformCoordinately
 )
 
+// ErrShutdownTimeout is returned by Shutdown when a ShutdownTimeout option
+// is given and the shutdown actions do not complete within that duration.
+// The actions themselves are not interrupted and continue running in the
+// background.
+var ErrShutdownTimeout = errors.New("safedown: timed out waiting for shutdown actions to complete")
+
+// ActionError pairs the position of a shutdown action, and its name if it
+// was added with AddNamedAction, with the error it returned or the error
+// that caused it to be abandoned (see ShutdownError).
+type ActionError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e ActionError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("action %q (index %d): %s", e.Name, e.Index, e.Err)
+	}
+
+	return fmt.Sprintf("action (index %d): %s", e.Index, e.Err)
+}
+
+func (e ActionError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError is returned by Shutdown and ShutdownContext when one or
+// more stored actions returned an error (only actions added with
+// AddActionsContext or AddNamedAction can return one), including any
+// action, of whichever kind, that was abandoned because the shutdown
+// context was done before it completed.
+type ShutdownError struct {
+	Errors []ActionError
+}
+
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, actionErr := range e.Errors {
+		msgs[i] = actionErr.Error()
+	}
+
+	return fmt.Sprintf("safedown: %d shutdown action(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// ActionHandle identifies an action registered with AddNamedAction,
+// allowing it to be deregistered with RemoveAction.
+type ActionHandle struct {
+	id uint64
+}
+
+// Trigger describes what caused shutdown to be triggered, passed to
+// Observer.OnShutdownStart.
+type Trigger struct {
+	// Signal is the signal that triggered shutdown, or nil if shutdown
+	// was triggered by an explicit call to Shutdown or ShutdownContext.
+	Signal os.Signal
+}
+
+// Observer receives callbacks describing the progress of the shutdown
+// process. Implementations are called synchronously from the goroutine
+// performing shutdown (or, for OnActionStart/OnActionEnd of an action
+// added after shutdown was triggered, from the background goroutine that
+// runs such actions) and should return quickly.
+type Observer interface {
+	// OnShutdownStart is called once, before any shutdown action runs.
+	OnShutdownStart(trigger Trigger)
+
+	// OnActionStart is called immediately before an action runs.
+	OnActionStart(name string)
+
+	// OnActionEnd is called once an action has either completed or been
+	// abandoned because the shutdown context's deadline was reached.
+	OnActionEnd(name string, dur time.Duration, err error)
+
+	// OnShutdownComplete is called once all shutdown actions have
+	// completed or been abandoned.
+	OnShutdownComplete(dur time.Duration, errs []error)
+}
+
+// EscalationPolicy determines how ShutdownActions responds when a
+// listened-for signal is delivered a second time while shutdown is
+// already in progress. The zero value is DefaultEscalation.
+type EscalationPolicy struct {
+	escalateOnRepeat bool
+	exitCode         *int
+}
+
+// DefaultEscalation preserves the original behaviour: a signal delivered
+// after the first has no special effect.
+var DefaultEscalation = EscalationPolicy{}
+
+// EscalateOnRepeat returns an EscalationPolicy under which the second
+// delivery of a listened-for signal immediately cancels the context the
+// stored actions are running under and abandons any action that has not
+// yet started, giving a "press once to shut down gracefully, twice to
+// force it" experience. Use WithExit to also terminate the process once
+// the remaining actions have been abandoned.
+func EscalateOnRepeat() EscalationPolicy {
+	return EscalationPolicy{escalateOnRepeat: true}
+}
+
+// WithExit returns a copy of the policy that calls os.Exit(code) once
+// escalation has cancelled the running actions' context and abandoned
+// the remaining ones.
+func (p EscalationPolicy) WithExit(code int) EscalationPolicy {
+	p.exitCode = &code
+	return p
+}
+
+// ShutdownResult describes the outcome of the shutdown process. It is
+// returned by Wait once the shutdown actions have completed.
+type ShutdownResult struct {
+	// ExitCode is the code the caller should pass to os.Exit. It is the
+	// highest exit code requested via the ExitCode option across all calls
+	// to Shutdown. If no ExitCode option was given then it defaults to
+	// 128+signum if shutdown was triggered by a signal, or 0 otherwise.
+	ExitCode int
+
+	// Signal is the signal that triggered shutdown, or nil if shutdown was
+	// triggered by an explicit call to Shutdown.
+	Signal os.Signal
+}
+
+// ShutdownOption configures the behaviour of a single call to Shutdown.
+type ShutdownOption interface {
+	apply(*shutdownOptions)
+}
+
+// shutdownOptions holds the resolved configuration from a set of
+// ShutdownOption values.
+type shutdownOptions struct {
+	exitCode        *int
+	shutdownTimeout time.Duration
+}
+
+// shutdownOptionFunc adapts a function to the ShutdownOption interface.
+type shutdownOptionFunc func(*shutdownOptions)
+
+func (f shutdownOptionFunc) apply(o *shutdownOptions) { f(o) }
+
+// ExitCode requests that code be used as the exit code surfaced in the
+// ShutdownResult. If multiple calls to Shutdown request an exit code the
+// highest one is used.
+func ExitCode(code int) ShutdownOption {
+	return shutdownOptionFunc(func(o *shutdownOptions) {
+		o.exitCode = &code
+	})
+}
+
+// ShutdownTimeout bounds how long Shutdown will block waiting for the
+// shutdown actions to complete. If the timeout elapses Shutdown returns
+// ErrShutdownTimeout, but the actions already in progress are left to
+// finish in the background.
+func ShutdownTimeout(d time.Duration) ShutdownOption {
+	return shutdownOptionFunc(func(o *shutdownOptions) {
+		o.shutdownTimeout = d
+	})
+}
+
 // ShutdownActions contains actions that are run when the os receives an interrupt signal.
 // This object must be created using the NewShutdownActions function.
 type ShutdownActions struct {
 	order        Order                // This determines the order the actions will be done.
-	actions      []func()             // The actions done on shutdown.
+	actions      []storedAction       // The actions done on shutdown.
 	onSignalFunc func(os.Signal)      // The function to be called when a signal is received.
 	strategy     PostShutdownStrategy // The strategy for actions after shutdown has been triggered
 
 	isShutdownTriggered       bool // This is true if the shutdown actions have been triggered
 	isProcessingStoredActions bool // This is true only while or immediately before stored actions are being processed.
 
+	triggerSignal os.Signal     // The signal (if any) that triggered shutdown.
+	exitCodeSet   bool          // This is true if an exit code has been requested via the ExitCode option.
+	exitCode      int           // The highest exit code requested via the ExitCode option.
+	actionErrors  []ActionError // The errors collected while running the stored actions on shutdown.
+
+	shutdownTimeout time.Duration   // The deadline given to actions when shutdown is triggered without an explicit context.
+	ctx             context.Context // The context the stored actions are run under once shutdown has started.
+	beforeShutdown  []func()        // Functions run before the stored actions, regardless of order, e.g. the cancel func from NewShutdownActionsWithContext.
+
+	actionSeq uint64   // The id given to the most recently added action.
+	observer  Observer // The observer notified of shutdown progress, if any.
+
+	escalation EscalationPolicy   // The policy applied to repeated signal deliveries.
+	cancelCtx  context.CancelFunc // Cancels the context the stored actions are run under; set once shutdown starts.
+
 	stopCh       chan struct{} // A channel to stop listening for signals.
 	stopOnce     sync.Once     // Ensures listening to signals is stopped once.
 	shutdownCh   chan struct{} // A channel that indicates if shutdown has been completed.
@@ -41,6 +222,23 @@ type ShutdownActions struct {
 	mutex        sync.Mutex    // A mutex to avoid clashes handling actions or onSignal.
 }
 
+// storedAction is a shutdown action together with the name (if any) it
+// was registered under.
+type storedAction struct {
+	id   uint64
+	name string
+	fn   func(context.Context) error
+}
+
+// ignoreContext adapts a context-less action to the storedAction's
+// func(context.Context) error signature.
+func ignoreContext(action func()) func(context.Context) error {
+	return func(context.Context) error {
+		action()
+		return nil
+	}
+}
+
 // NewShutdownActions creates and initialises a new set of shutdown actions.
 // The actions (added later) will be executed if any of the signals provided are received.
 // The order determines the order the actions will be executed.
@@ -64,36 +262,174 @@ func NewShutdownActions(order Order, signals ...os.Signal) *ShutdownActions {
 
 	// Starts a go routine for listening for signals and close messages
 	go func() {
-		// Listens for signal or close message
+		// Listens for the first signal or close message.
 		var received os.Signal
 		select {
 		case <-sa.stopCh:
+			signal.Stop(signalCh)
+			close(signalCh)
+			return
 		case received = <-signalCh:
 		}
 
-		// Stops listening for signals and closes channels
-		signal.Stop(signalCh)
-		close(signalCh)
+		// A signal has been received: shutdown is triggered below, so
+		// there is no longer a need to listen for the stop channel.
+		// Keep the notifier registered, though, so repeated deliveries
+		// can still be counted for the signal escalation policy.
 		sa.closeStopCh()
 
-		// Runs on signal and shutdown actions
+		// Records the signal that triggered shutdown so it can be
+		// surfaced in the ShutdownResult returned by Wait.
+		sa.mutex.Lock()
+		sa.triggerSignal = received
+		sa.mutex.Unlock()
+
+		// Runs on signal and shutdown actions. Shutdown runs in its own
+		// goroutine so that this one can keep listening for repeated
+		// signal deliveries while it is in progress.
 		sa.onSignal(received)
-		sa.shutdown()
+		go func() {
+			ctx, cancel := sa.resolveContext(nil)
+			defer cancel()
+			sa.shutdown(ctx)
+		}()
+
+		// Listens for further signal deliveries until shutdown
+		// completes, applying the signal escalation policy (if any) to
+		// the second delivery.
+		signalCount := 1
+		for {
+			select {
+			case <-sa.shutdownCh:
+				signal.Stop(signalCh)
+				close(signalCh)
+				return
+			case <-signalCh:
+				signalCount++
+				if signalCount < 2 {
+					continue
+				}
+
+				sa.mutex.Lock()
+				policy := sa.escalation
+				sa.mutex.Unlock()
+
+				if !policy.escalateOnRepeat {
+					continue
+				}
+
+				signal.Stop(signalCh)
+				close(signalCh)
+				sa.escalate(policy)
+				return
+			}
+		}
 	}()
 
 	return sa
 }
 
+// NewShutdownActionsWithContext creates and initialises a new set of
+// shutdown actions exactly as NewShutdownActions does, and additionally
+// returns a context derived from parent. That context is cancelled the
+// moment shutdown is triggered, whether by one of the signals provided
+// being received or by a call to Shutdown or ShutdownContext -- this is
+// analogous to signal.NotifyContext in the standard library. The context
+// is guaranteed to be cancelled before any of the registered actions are
+// run, giving long-running goroutines a chance to notice and unwind
+// while the shutdown actions execute.
+func NewShutdownActionsWithContext(parent context.Context, order Order, signals ...os.Signal) (*ShutdownActions, context.Context) {
+	sa := NewShutdownActions(order, signals...)
+
+	ctx, cancel := context.WithCancel(parent)
+	sa.mutex.Lock()
+	sa.beforeShutdown = append(sa.beforeShutdown, cancel)
+	sa.mutex.Unlock()
+
+	return sa, ctx
+}
+
 // AddActions adds actions to be run on Shutdown or when a signal is received.
 //
 // Any action added after shutdown has been triggered will be handled according
 // to the post shutdown strategy.
 func (sa *ShutdownActions) AddActions(actions ...func()) {
+	stored := make([]storedAction, len(actions))
+	for i, action := range actions {
+		stored[i] = storedAction{fn: ignoreContext(action)}
+	}
+
+	sa.addStoredActions(stored)
+}
+
+// AddActionsContext adds context-aware actions to be run on Shutdown,
+// ShutdownContext, or when a signal is received. Each action is given the
+// context that shutdown was triggered with (see ShutdownContext and
+// SetShutdownTimeout); an action that does not return before that
+// context's deadline is abandoned and a timeout error is recorded for it,
+// while the remaining actions are still started. They share the same,
+// by then expired, context, so any of them that does not return at once
+// is abandoned with a timeout error too.
+//
+// Any action added after shutdown has been triggered will be handled
+// according to the post shutdown strategy.
+func (sa *ShutdownActions) AddActionsContext(actions ...func(context.Context) error) {
+	stored := make([]storedAction, len(actions))
+	for i, action := range actions {
+		stored[i] = storedAction{fn: action}
+	}
+
+	sa.addStoredActions(stored)
+}
+
+// AddNamedAction adds a single named, error-returning action to be run on
+// Shutdown, ShutdownContext, or when a signal is received, and returns a
+// handle that can be used to deregister it with RemoveAction before it
+// runs. The name is passed to the Observer set via SetShutdownObserver
+// and recorded in any resulting ActionError.
+//
+// Any action added after shutdown has been triggered will be handled
+// according to the post shutdown strategy.
+func (sa *ShutdownActions) AddNamedAction(name string, fn func() error) ActionHandle {
+	handles := sa.addStoredActions([]storedAction{
+		{name: name, fn: func(context.Context) error { return fn() }},
+	})
+
+	return handles[0]
+}
+
+// RemoveAction deregisters the action identified by handle, provided it
+// has not already started running. It is a no-op if the action has
+// already completed, is currently running, or was already removed.
+func (sa *ShutdownActions) RemoveAction(handle ActionHandle) {
+	sa.mutex.Lock()
+	defer sa.mutex.Unlock()
+
+	for i, action := range sa.actions {
+		if action.id == handle.id {
+			sa.actions = append(sa.actions[:i], sa.actions[i+1:]...)
+			return
+		}
+	}
+}
+
+// addStoredActions is the shared implementation behind AddActions,
+// AddActionsContext and AddNamedAction. It assigns each action an id,
+// returning the resulting handles in the same order as actions.
+func (sa *ShutdownActions) addStoredActions(actions []storedAction) []ActionHandle {
 	sa.mutex.Lock()
+
+	handles := make([]ActionHandle, len(actions))
+	for i := range actions {
+		sa.actionSeq++
+		actions[i].id = sa.actionSeq
+		handles[i] = ActionHandle{id: actions[i].id}
+	}
+
 	if !sa.isShutdownTriggered {
 		sa.actions = append(sa.actions, actions...)
 		sa.mutex.Unlock()
-		return
+		return handles
 	}
 
 	// The decision to perform the actions in the background is a pragmatic one.
@@ -101,26 +437,28 @@ func (sa *ShutdownActions) AddActions(actions ...func()) {
 	// mechanism to record if the actions had been performed which would require
 	// significant changes.
 
+	ctx := sa.ctx
 	switch sa.strategy {
 	case PerformImmediately:
 		sa.mutex.Unlock()
-		go sa.performActions(actions)
-		return
+		go sa.performActions(ctx, actions)
+		return handles
 	case PerformCoordinately:
 		sa.actions = append(sa.actions, actions...)
 		if sa.isProcessingStoredActions {
 			sa.mutex.Unlock()
-			return
+			return handles
 		}
 
 		sa.isProcessingStoredActions = true
 		sa.mutex.Unlock()
-		go sa.performStoredActions()
+		go sa.performStoredActions(ctx)
 	default:
 		sa.mutex.Unlock()
-		return
+		return handles
 	}
 
+	return handles
 }
 
 // SetOnSignal sets the method which will be called if a signal is received.
@@ -130,14 +468,179 @@ func (sa *ShutdownActions) SetOnSignal(onSignal func(os.Signal)) {
 	sa.mutex.Unlock()
 }
 
+// SetShutdownObserver sets the observer notified of shutdown progress,
+// replacing any observer set previously.
+func (sa *ShutdownActions) SetShutdownObserver(observer Observer) {
+	sa.mutex.Lock()
+	sa.observer = observer
+	sa.mutex.Unlock()
+}
+
+// getObserver returns the currently set observer, or nil.
+func (sa *ShutdownActions) getObserver() Observer {
+	sa.mutex.Lock()
+	defer sa.mutex.Unlock()
+	return sa.observer
+}
+
+// SetSignalEscalation sets the policy applied when a listened-for signal
+// is delivered a second time while shutdown is already in progress.
+func (sa *ShutdownActions) SetSignalEscalation(policy EscalationPolicy) {
+	sa.mutex.Lock()
+	sa.escalation = policy
+	sa.mutex.Unlock()
+}
+
+// escalate implements the EscalateOnRepeat policy: it cancels the
+// context the stored actions are running under, so actions waiting on it
+// can unwind, abandons any action that has not yet started, and -- if
+// the policy was given an exit code via WithExit -- terminates the
+// process.
+func (sa *ShutdownActions) escalate(policy EscalationPolicy) {
+	sa.mutex.Lock()
+	sa.actions = nil
+	cancel := sa.cancelCtx
+	sa.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	if policy.exitCode != nil {
+		os.Exit(*policy.exitCode)
+	}
+}
+
 // Shutdown runs the shutdown actions and stops listening
 // for signals (if doing so). This method blocks until all
 // shutdown actions have been run, regardless of if they
 // have been triggered by receiving a signal or calling this
 // method.
-func (sa *ShutdownActions) Shutdown() {
+//
+// The actions run under the background context, given a deadline if one
+// was set via SetShutdownTimeout. Use ShutdownContext to run the actions
+// under a different context.
+//
+// An ExitCode option can be given to request the exit code surfaced in
+// the ShutdownResult returned by Wait; the highest exit code requested
+// across all calls to Shutdown wins. A ShutdownTimeout option bounds how
+// long this call blocks before returning ErrShutdownTimeout; the
+// shutdown actions are left to finish in the background regardless.
+//
+// If one or more actions returned an error, or were abandoned because
+// the shutdown deadline was reached, a *ShutdownError is returned.
+func (sa *ShutdownActions) Shutdown(opts ...ShutdownOption) error {
+	options := shutdownOptions{}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+	sa.applyShutdownOptions(options)
+
+	ctx, cancel := sa.resolveContext(nil)
+
+	sa.closeStopCh()
+
+	if options.shutdownTimeout <= 0 {
+		sa.shutdown(ctx)
+		cancel()
+		return sa.collectActionErrors()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sa.shutdown(ctx)
+		cancel()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return sa.collectActionErrors()
+	case <-time.After(options.shutdownTimeout):
+		return ErrShutdownTimeout
+	}
+}
+
+// ShutdownContext runs the shutdown actions under ctx, and stops
+// listening for signals (if doing so). This method blocks until all
+// shutdown actions have been run or abandoned because ctx's deadline was
+// reached.
+//
+// If ctx has no deadline of its own, a deadline set via
+// SetShutdownTimeout is applied on top of it.
+//
+// If one or more actions returned an error, or were abandoned because
+// ctx's deadline was reached, a *ShutdownError is returned.
+func (sa *ShutdownActions) ShutdownContext(ctx context.Context) error {
+	resolved, cancel := sa.resolveContext(ctx)
+	defer cancel()
+
 	sa.closeStopCh()
-	sa.shutdown()
+	sa.shutdown(resolved)
+	return sa.collectActionErrors()
+}
+
+// SetShutdownTimeout sets the deadline given to the context that
+// context-aware actions run under when shutdown is triggered without an
+// explicit context, i.e. via Shutdown or a received signal. It has no
+// effect on ShutdownContext calls whose ctx already carries a deadline.
+func (sa *ShutdownActions) SetShutdownTimeout(d time.Duration) {
+	sa.mutex.Lock()
+	sa.shutdownTimeout = d
+	sa.mutex.Unlock()
+}
+
+// resolveContext derives the context that the stored actions should run
+// under from ctx (the background context if ctx is nil), applying the
+// deadline set via SetShutdownTimeout if ctx does not already have one.
+func (sa *ShutdownActions) resolveContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	sa.mutex.Lock()
+	d := sa.shutdownTimeout
+	sa.mutex.Unlock()
+
+	if d <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, d)
+}
+
+// collectActionErrors returns the errors recorded while running the
+// stored actions during the last shutdown, wrapped in a *ShutdownError,
+// or nil if there were none.
+func (sa *ShutdownActions) collectActionErrors() error {
+	sa.mutex.Lock()
+	errs := sa.actionErrors
+	sa.mutex.Unlock()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ShutdownError{Errors: errs}
+}
+
+// applyShutdownOptions records the resolved options against the
+// shutdown actions, keeping the highest requested exit code.
+func (sa *ShutdownActions) applyShutdownOptions(options shutdownOptions) {
+	if options.exitCode == nil {
+		return
+	}
+
+	sa.mutex.Lock()
+	if !sa.exitCodeSet || *options.exitCode > sa.exitCode {
+		sa.exitCodeSet = true
+		sa.exitCode = *options.exitCode
+	}
+	sa.mutex.Unlock()
 }
 
 // UsePostShutdownStrategy sets the strategy for actions added after shutdown
@@ -150,9 +653,16 @@ func (sa *ShutdownActions) UsePostShutdownStrategy(strategy PostShutdownStrategy
 }
 
 // Wait waits until all the shutdown actions have been
-// called.
-func (sa *ShutdownActions) Wait() {
+// called and returns the ShutdownResult, so callers can do
+// os.Exit(sa.Wait().ExitCode) once shutdown has completed.
+func (sa *ShutdownActions) Wait() ShutdownResult {
 	<-sa.shutdownCh
+	sa.mutex.Lock()
+	defer sa.mutex.Unlock()
+	return ShutdownResult{
+		ExitCode: sa.resolveExitCode(),
+		Signal:   sa.triggerSignal,
+	}
 }
 
 // closeStopCh closes the stop channel
@@ -182,25 +692,34 @@ func (sa *ShutdownActions) onSignal(s os.Signal) {
 	onSignal(s)
 }
 
-func (sa *ShutdownActions) performActions(actions []func()) {
+// performActions runs the actions given, in the order dictated by
+// sa.order, using ctx as their context. Errors and timeouts are not
+// recorded here, matching the fire-and-forget nature of the
+// PerformImmediately strategy.
+func (sa *ShutdownActions) performActions(ctx context.Context, actions []storedAction) {
 	for i := range actions {
 		if sa.order == FirstInFirstDone {
-			actions[i]()
+			_ = sa.runAction(ctx, actions[i])
 		} else {
-			actions[len(actions)-i-1]()
+			_ = sa.runAction(ctx, actions[len(actions)-i-1])
 		}
 	}
 }
 
-func (sa *ShutdownActions) performStoredActions() {
+// performStoredActions drains sa.actions, in the order dictated by
+// sa.order, running each one under ctx and collecting any errors (or
+// timeouts) it produces.
+func (sa *ShutdownActions) performStoredActions(ctx context.Context) []ActionError {
+	var errs []ActionError
+	index := 0
 	for {
-		var action func()
+		var action storedAction
 		sa.mutex.Lock()
 		switch {
 		case len(sa.actions) == 0:
 			sa.isProcessingStoredActions = false
 			sa.mutex.Unlock()
-			return
+			return errs
 		case sa.order == FirstInLastDone:
 			action = sa.actions[len(sa.actions)-1]
 			sa.actions = sa.actions[:len(sa.actions)-1]
@@ -210,20 +729,88 @@ func (sa *ShutdownActions) performStoredActions() {
 		}
 		sa.mutex.Unlock()
 
-		action()
+		if err := sa.runAction(ctx, action); err != nil {
+			errs = append(errs, ActionError{Index: index, Name: action.name, Err: err})
+		}
+		index++
 	}
 }
 
-// shutdown runs the shutdown actions
-func (sa *ShutdownActions) shutdown() {
+// runAction runs action under ctx, abandoning it if ctx is done before it
+// completes. An abandoned action keeps running in the background; its
+// eventual result, if any, is discarded.
+func (sa *ShutdownActions) runAction(ctx context.Context, action storedAction) error {
+	observer := sa.getObserver()
+	if observer != nil {
+		observer.OnActionStart(action.name)
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- action.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if observer != nil {
+		observer.OnActionEnd(action.name, time.Since(start), err)
+	}
+
+	return err
+}
+
+// shutdown runs the shutdown actions under ctx.
+func (sa *ShutdownActions) shutdown(ctx context.Context) {
 	sa.shutdownOnce.Do(
 		func() {
+			// cancelRun is not deferred here: runCtx is stored on sa.ctx
+			// and reused by actions added after shutdown has completed
+			// (see addStoredActions). It is cancelled only by an
+			// escalation or when ctx itself is done.
+			runCtx, cancelRun := context.WithCancel(ctx)
+
 			sa.mutex.Lock()
 			sa.isShutdownTriggered = true
 			sa.isProcessingStoredActions = true
+			sa.ctx = runCtx
+			sa.cancelCtx = cancelRun
+			beforeShutdown := sa.beforeShutdown
+			observer := sa.observer
+			triggerSignal := sa.triggerSignal
+			sa.mutex.Unlock()
+
+			if observer != nil {
+				observer.OnShutdownStart(Trigger{Signal: triggerSignal})
+			}
+
+			// Runs before the stored actions, regardless of order, so
+			// e.g. a context returned by NewShutdownActionsWithContext
+			// is always cancelled before the registered actions run.
+			for _, fn := range beforeShutdown {
+				fn()
+			}
+
+			start := time.Now()
+			errs := sa.performStoredActions(runCtx)
+			dur := time.Since(start)
+
+			sa.mutex.Lock()
+			sa.actionErrors = errs
 			sa.mutex.Unlock()
 
-			sa.performStoredActions()
+			if observer != nil {
+				genericErrs := make([]error, len(errs))
+				for i, actionErr := range errs {
+					genericErrs[i] = actionErr
+				}
+				observer.OnShutdownComplete(dur, genericErrs)
+			}
 
 			// Closes the shutdown channel indicating shutdown
 			// is complete.
@@ -231,3 +818,28 @@ func (sa *ShutdownActions) shutdown() {
 		},
 	)
 }
+
+// resolveExitCode returns the exit code requested via the ExitCode option,
+// or the exit code derived from the triggering signal if none was
+// requested. sa.mutex must be held by the caller.
+func (sa *ShutdownActions) resolveExitCode() int {
+	if sa.exitCodeSet {
+		return sa.exitCode
+	}
+
+	return signalExitCode(sa.triggerSignal)
+}
+
+// signalExitCode returns the conventional shell exit code (128+signum)
+// for the signal given, or 0 if the signal is nil.
+func signalExitCode(s os.Signal) int {
+	if s == nil {
+		return 0
+	}
+
+	if sig, ok := s.(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+
+	return 1
+}
@@ -1,6 +1,8 @@
 package safedown_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -225,6 +227,265 @@ func TestNewShutdownActions_PostShutdownStrategy_PerformImmediately(t *testing.T
 	sa.AddActions(counterWithDelay(t, &wg, 4, &count, 10*time.Millisecond))
 }
 
+// TestShutdownActions_Wait_ExitCode checks that the ExitCode option is
+// surfaced in the ShutdownResult returned by Wait, and that the highest
+// of multiple requested exit codes wins.
+func TestShutdownActions_Wait_ExitCode(t *testing.T) {
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone)
+
+	if err := sa.Shutdown(safedown.ExitCode(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sa.Shutdown(safedown.ExitCode(3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := sa.Wait()
+	if result.ExitCode != 3 {
+		t.Fatalf("exit code was %d instead of 3", result.ExitCode)
+	}
+	if result.Signal != nil {
+		t.Fatalf("signal was %s instead of nil", result.Signal)
+	}
+}
+
+// TestShutdownActions_Wait_SignalExitCode checks that, absent an ExitCode
+// option, the exit code defaults to 128+signum and the triggering signal
+// is recorded.
+// nolint: gomnd
+func TestShutdownActions_Wait_SignalExitCode(t *testing.T) {
+	expected := os.Interrupt
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone, expected)
+
+	sendSignalToSelf(t, expected)
+
+	result := sa.Wait()
+	if result.Signal != expected {
+		t.Fatalf("signal was %s instead of %s", result.Signal, expected)
+	}
+	if result.ExitCode == 0 {
+		t.Fatal("exit code was 0 instead of a signal derived exit code")
+	}
+}
+
+// TestShutdownActions_Shutdown_Timeout checks that Shutdown returns
+// ErrShutdownTimeout when the ShutdownTimeout option elapses before the
+// actions have completed, without interrupting those actions.
+func TestShutdownActions_Shutdown_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone)
+	sa.AddActions(func() {
+		time.Sleep(50 * time.Millisecond)
+		close(done)
+	})
+
+	err := sa.Shutdown(safedown.ShutdownTimeout(time.Millisecond))
+	if !errors.Is(err, safedown.ErrShutdownTimeout) {
+		t.Fatalf("error was %v instead of %v", err, safedown.ErrShutdownTimeout)
+	}
+
+	<-done
+}
+
+// TestShutdownActions_ShutdownContext_ActionError checks that an error
+// returned by a context-aware action is surfaced as a *ShutdownError from
+// ShutdownContext.
+func TestShutdownActions_ShutdownContext_ActionError(t *testing.T) {
+	expected := fmt.Errorf("could not flush buffer")
+
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone)
+	sa.AddActionsContext(func(context.Context) error {
+		return expected
+	})
+
+	err := sa.ShutdownContext(context.Background())
+
+	var shutdownErr *safedown.ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("error was %v instead of a *safedown.ShutdownError", err)
+	}
+	if len(shutdownErr.Errors) != 1 || !errors.Is(shutdownErr.Errors[0].Err, expected) {
+		t.Fatalf("action errors were %v instead of [%v]", shutdownErr.Errors, expected)
+	}
+}
+
+// TestShutdownActions_ShutdownContext_Timeout checks that a context-aware
+// action which ignores the deadline is abandoned, a timeout error is
+// recorded for it, and the remaining actions still run.
+func TestShutdownActions_ShutdownContext_Timeout(t *testing.T) {
+	wg := sync.WaitGroup{}
+	defer addWaitGroupDeadline(t, &wg, time.Now().Add(time.Second))
+	wg.Add(1)
+
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone)
+	sa.AddActionsContext(func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	sa.AddActionsContext(func(context.Context) error {
+		wg.Done()
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := sa.ShutdownContext(ctx)
+
+	var shutdownErr *safedown.ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("error was %v instead of a *safedown.ShutdownError", err)
+	}
+	if len(shutdownErr.Errors) != 1 || !errors.Is(shutdownErr.Errors[0].Err, context.DeadlineExceeded) {
+		t.Fatalf("action errors were %v instead of [%v]", shutdownErr.Errors, context.DeadlineExceeded)
+	}
+}
+
+// TestNewShutdownActionsWithContext checks that the context returned is
+// cancelled by Shutdown, and before the registered actions are run.
+func TestNewShutdownActionsWithContext(t *testing.T) {
+	cancelledBeforeAction := false
+
+	sa, ctx := safedown.NewShutdownActionsWithContext(context.Background(), safedown.FirstInLastDone)
+	sa.AddActions(func() {
+		cancelledBeforeAction = ctx.Err() != nil
+	})
+
+	sa.Shutdown()
+
+	if ctx.Err() == nil {
+		t.Fatal("context was not cancelled by Shutdown")
+	}
+	if !cancelledBeforeAction {
+		t.Fatal("context was not cancelled before the registered action ran")
+	}
+}
+
+// TestShutdownActions_RemoveAction checks that an action removed via
+// RemoveAction before shutdown is not run, while an action that is not
+// removed still is.
+func TestShutdownActions_RemoveAction(t *testing.T) {
+	removedRan := false
+	keptRan := false
+
+	sa := safedown.NewShutdownActions(safedown.FirstInLastDone)
+	handle := sa.AddNamedAction("removed", func() error {
+		removedRan = true
+		return nil
+	})
+	sa.AddNamedAction("kept", func() error {
+		keptRan = true
+		return nil
+	})
+
+	sa.RemoveAction(handle)
+	sa.Shutdown()
+
+	if removedRan {
+		t.Fatal("removed action ran")
+	}
+	if !keptRan {
+		t.Fatal("kept action did not run")
+	}
+}
+
+// TestShutdownActions_SetSignalEscalation_EscalateOnRepeat checks that,
+// under EscalateOnRepeat, a second delivery of the listened-for signal
+// cancels the context actions are running under and abandons any action
+// that has not yet started.
+func TestShutdownActions_SetSignalEscalation_EscalateOnRepeat(t *testing.T) {
+	wg := sync.WaitGroup{}
+	defer addWaitGroupDeadline(t, &wg, time.Now().Add(3*time.Second))
+
+	expected := os.Interrupt
+	sa := safedown.NewShutdownActions(safedown.FirstInFirstDone, expected)
+	sa.SetSignalEscalation(safedown.EscalateOnRepeat())
+
+	started := make(chan struct{})
+	wg.Add(1)
+	sa.AddActionsContext(func(ctx context.Context) error {
+		defer wg.Done()
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	abandonedRan := false
+	sa.AddActions(func() {
+		abandonedRan = true
+	})
+
+	sendSignalToSelf(t, expected)
+	<-started
+	sendSignalToSelf(t, expected)
+
+	wg.Wait()
+
+	if abandonedRan {
+		t.Fatal("action added after the long-running one ran despite escalation")
+	}
+
+	result := sa.Wait()
+	if result.Signal != expected {
+		t.Fatalf("signal was %s instead of %s", result.Signal, expected)
+	}
+}
+
+// observerRecorder is a test Observer that records the names of the
+// actions it is notified about.
+type observerRecorder struct {
+	mu      sync.Mutex
+	started []string
+	ended   []string
+	errs    []error
+}
+
+func (o *observerRecorder) OnShutdownStart(safedown.Trigger) {}
+
+func (o *observerRecorder) OnActionStart(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.started = append(o.started, name)
+}
+
+func (o *observerRecorder) OnActionEnd(name string, _ time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ended = append(o.ended, name)
+}
+
+func (o *observerRecorder) OnShutdownComplete(_ time.Duration, errs []error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errs = errs
+}
+
+// TestShutdownActions_SetShutdownObserver checks that the observer is
+// notified of each named action starting and ending, as well as the
+// errors collected over the whole shutdown.
+func TestShutdownActions_SetShutdownObserver(t *testing.T) {
+	expected := fmt.Errorf("could not flush buffer")
+
+	observer := &observerRecorder{}
+	sa := safedown.NewShutdownActions(safedown.FirstInFirstDone)
+	sa.SetShutdownObserver(observer)
+	sa.AddNamedAction("flush", func() error {
+		return expected
+	})
+
+	sa.Shutdown()
+
+	if len(observer.started) != 1 || observer.started[0] != "flush" {
+		t.Fatalf("started actions were %v instead of [flush]", observer.started)
+	}
+	if len(observer.ended) != 1 || observer.ended[0] != "flush" {
+		t.Fatalf("ended actions were %v instead of [flush]", observer.ended)
+	}
+	if len(observer.errs) != 1 || !errors.Is(observer.errs[0], expected) {
+		t.Fatalf("errs were %v instead of [%v]", observer.errs, expected)
+	}
+}
+
 // addWaitGroupDeadline adds waits till either the wait group
 // is done or until the deadline is reached. If the deadline
 // is reached then test fails.